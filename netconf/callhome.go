@@ -0,0 +1,219 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Default ports for RFC 8071 call home, per IANA allocation.
+const (
+	CallHomeSSHPort = 4334
+	CallHomeTLSPort = 4335
+)
+
+// Server accepts inbound NETCONF call home connections and hands each
+// hello-exchanged Session to a handler. It supports graceful shutdown via
+// Shutdown.
+type Server struct {
+	listener net.Listener
+	handler  func(*Session)
+
+	// KeepaliveInterval, when non-zero, sets the interval at which the
+	// server sends SSH keepalive requests on persistent call home
+	// connections, per RFC 8071 section 3.1.
+	KeepaliveInterval time.Duration
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// ListenAndServeCallHome binds addr, accepts inbound SSH call home
+// connections, performs the SSH and NETCONF hello handshakes as the client
+// end of the session, and invokes handler for each established Session.
+// It blocks until accepting on the listener fails. The Server it creates is
+// not returned, so it cannot be shut down; use NewCallHomeServer and
+// ServeSSH for a server that supports Shutdown and KeepaliveInterval.
+func ListenAndServeCallHome(addr string, sshConfig *ssh.ClientConfig, handler func(*Session)) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &Server{listener: ln, handler: handler, done: make(chan struct{})}
+	return srv.serveSSH(sshConfig)
+}
+
+// ListenAndServeCallHomeTLS is the TLS transport variant of
+// ListenAndServeCallHome. Per RFC 8071 the listener is still the TLS
+// client: the calling device presents its certificate and is
+// authenticated against tlsConfig, so the listener binds a plain TCP
+// socket and performs the TLS client handshake on each accepted conn.
+func ListenAndServeCallHomeTLS(addr string, tlsConfig *tls.Config, handler func(*Session)) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &Server{listener: ln, handler: handler, done: make(chan struct{})}
+	return srv.serveTLS(tlsConfig)
+}
+
+// NewCallHomeServer wraps an already-bound listener, allowing callers to
+// customize socket options before handing control to the server.
+func NewCallHomeServer(ln net.Listener, handler func(*Session)) *Server {
+	return &Server{listener: ln, handler: handler, done: make(chan struct{})}
+}
+
+// ServeSSH accepts connections on the server's listener, treating each one
+// as an inbound SSH call home connection, until Shutdown is called.
+func (s *Server) ServeSSH(sshConfig *ssh.ClientConfig) error {
+	return s.serveSSH(sshConfig)
+}
+
+func (s *Server) serveSSH(sshConfig *ssh.ClientConfig) error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+				return nil
+			default:
+				return err
+			}
+		}
+		go s.handleSSHConn(conn, sshConfig)
+	}
+}
+
+// ServeTLS accepts connections on the server's listener, treating each one
+// as an inbound TLS call home connection, until Shutdown is called.
+func (s *Server) ServeTLS(tlsConfig *tls.Config) error {
+	return s.serveTLS(tlsConfig)
+}
+
+func (s *Server) serveTLS(tlsConfig *tls.Config) error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+				return nil
+			default:
+				return err
+			}
+		}
+		go s.handleTLSConn(conn, tlsConfig)
+	}
+}
+
+func (s *Server) handleTLSConn(conn net.Conn, tlsConfig *tls.Config) {
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(context.Background()); err != nil {
+		conn.Close()
+		return
+	}
+	s.handleEstablished(tlsConn)
+}
+
+func (s *Server) handleSSHConn(conn net.Conn, sshConfig *ssh.ClientConfig) {
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, conn.RemoteAddr().String(), sshConfig)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+
+	if s.KeepaliveInterval > 0 {
+		go keepalive(client, s.KeepaliveInterval, s.done)
+	}
+
+	sess, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return
+	}
+	stdin, err := sess.StdinPipe()
+	if err != nil {
+		client.Close()
+		return
+	}
+	stdout, err := sess.StdoutPipe()
+	if err != nil {
+		client.Close()
+		return
+	}
+	if err := sess.RequestSubsystem("netconf"); err != nil {
+		client.Close()
+		return
+	}
+
+	s.handleEstablished(&sshTransport{stdin: stdin, stdout: stdout, closer: client})
+}
+
+func (s *Server) handleEstablished(transport io.ReadWriteCloser) {
+	session, err := NewSession(transport)
+	if err != nil {
+		transport.Close()
+		return
+	}
+	s.handler(session)
+}
+
+// sshTransport adapts the stdin/stdout pipes of an SSH "netconf" subsystem
+// session into the single io.ReadWriteCloser NewSession expects.
+type sshTransport struct {
+	stdin  io.WriteCloser
+	stdout io.Reader
+	closer io.Closer
+}
+
+func (t *sshTransport) Read(p []byte) (int, error)  { return t.stdout.Read(p) }
+func (t *sshTransport) Write(p []byte) (int, error) { return t.stdin.Write(p) }
+func (t *sshTransport) Close() error                { return t.closer.Close() }
+
+// Shutdown gracefully stops the server: it closes the listener so Accept
+// unblocks and Serve* returns nil, then returns once the listener has
+// closed. It is safe to call Shutdown more than once. ctx bounds how long
+// Shutdown waits for the listener to close; it does not wait for accepted
+// sessions to finish.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.closeOnce.Do(func() { close(s.done) })
+
+	closed := make(chan error, 1)
+	go func() { closed <- s.listener.Close() }()
+
+	select {
+	case err := <-closed:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// keepalive periodically sends an SSH keepalive request on client until
+// done is closed, per RFC 8071 section 3.1's guidance for long-lived
+// persistent call home connections.
+func keepalive(client *ssh.Client, interval time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
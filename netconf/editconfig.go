@@ -0,0 +1,181 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// XPathFilter selects config or state via an XPath expression rather than
+// a subtree filter, per RFC 6241 section 8.9. The remote peer must
+// advertise the :xpath capability.
+type XPathFilter string
+
+// EditOpt customizes the <edit-config> RPC produced by EditConfig.
+type EditOpt func(*editConfigOptions)
+
+type editConfigOptions struct {
+	defaultOperation string
+	testOption       string
+	errorOption      string
+	operations       map[string]string
+}
+
+// WithDefaultOperation sets <default-operation> to "merge", "replace", or
+// "none".
+func WithDefaultOperation(op string) EditOpt {
+	return func(o *editConfigOptions) { o.defaultOperation = op }
+}
+
+// WithTestOption sets <test-option> to "test-then-set", "set", or
+// "test-only". Requires the remote peer to advertise the :validate
+// capability.
+func WithTestOption(opt string) EditOpt {
+	return func(o *editConfigOptions) { o.testOption = opt }
+}
+
+// WithErrorOption sets <error-option> to "stop-on-error",
+// "continue-on-error", or "rollback-on-error".
+func WithErrorOption(opt string) EditOpt {
+	return func(o *editConfigOptions) { o.errorOption = opt }
+}
+
+// WithOperation stamps operation="op" onto the payload element addressed
+// by xpath, a slash-separated path of element local names (e.g.
+// "security/address-book/address"), where op is one of "delete",
+// "remove", "create", "replace", or "merge".
+func WithOperation(xpath, op string) EditOpt {
+	return func(o *editConfigOptions) {
+		if o.operations == nil {
+			o.operations = make(map[string]string)
+		}
+		o.operations[xpath] = op
+	}
+}
+
+// EditConfig builds an <edit-config> RPC against target ("candidate" or
+// "running") carrying payload as the <config> body. payload may be a
+// string, []byte, or xml.Marshaler already rendering well-formed XML.
+func EditConfig(target string, payload interface{}, opts ...EditOpt) (RawMethod, error) {
+	var options editConfigOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	body, err := marshalPayload(payload)
+	if err != nil {
+		return "", err
+	}
+	if len(options.operations) > 0 {
+		if body, err = applyOperations(body, options.operations); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<edit-config>")
+	fmt.Fprintf(&buf, "<target><%s/></target>", target)
+	if options.defaultOperation != "" {
+		fmt.Fprintf(&buf, "<default-operation>%s</default-operation>", options.defaultOperation)
+	}
+	if options.testOption != "" {
+		fmt.Fprintf(&buf, "<test-option>%s</test-option>", options.testOption)
+	}
+	if options.errorOption != "" {
+		fmt.Fprintf(&buf, "<error-option>%s</error-option>", options.errorOption)
+	}
+	buf.WriteString("<config>")
+	buf.Write(body)
+	buf.WriteString("</config></edit-config>")
+
+	return RawMethod(buf.String()), nil
+}
+
+// marshalPayload renders payload to raw XML, accepting a string, []byte,
+// xml.Marshaler, or any other value encoding/xml can marshal.
+func marshalPayload(payload interface{}) ([]byte, error) {
+	switch v := payload.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+		return xml.Marshal(v)
+	}
+}
+
+// renderFilter renders a get/get-config <filter> element from filter,
+// which may be nil (no filter), an XPathFilter, or a subtree payload
+// accepted by marshalPayload.
+func renderFilter(filter interface{}) (string, error) {
+	if filter == nil {
+		return "", nil
+	}
+	if xp, ok := filter.(XPathFilter); ok {
+		return fmt.Sprintf(`<filter type="xpath" select=%q/>`, string(xp)), nil
+	}
+	body, err := marshalPayload(filter)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`<filter type="subtree">%s</filter>`, body), nil
+}
+
+// applyOperations walks body's XML tree and stamps operation="op" onto
+// every element whose path of local names (joined with "/") matches a key
+// in ops.
+func applyOperations(body []byte, ops map[string]string) ([]byte, error) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	var out bytes.Buffer
+	enc := xml.NewEncoder(&out)
+
+	var path []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			if op, ok := ops[strings.Join(path, "/")]; ok {
+				t.Attr = setOperationAttr(t.Attr, op)
+			}
+			tok = t
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		}
+
+		if err := enc.EncodeToken(tok); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// setOperationAttr returns attrs with its "operation" attribute set to
+// op, adding one if none is present.
+func setOperationAttr(attrs []xml.Attr, op string) []xml.Attr {
+	for i, a := range attrs {
+		if a.Name.Local == "operation" {
+			attrs[i].Value = op
+			return attrs
+		}
+	}
+	return append(attrs, xml.Attr{Name: xml.Name{Local: "operation"}, Value: op})
+}
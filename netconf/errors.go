@@ -0,0 +1,70 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import "fmt"
+
+// ErrorInfo holds the parsed children of an <rpc-error>'s <error-info>
+// element, as defined by RFC 6241 section 4.3. RawInfo preserves the full
+// inner XML so callers can still reach vendor-specific extensions that
+// aren't modeled by the typed fields above.
+type ErrorInfo struct {
+	BadAttribute string `xml:"bad-attribute,omitempty"`
+	BadElement   string `xml:"bad-element,omitempty"`
+	BadNamespace string `xml:"bad-namespace,omitempty"`
+	SessionID    string `xml:"session-id,omitempty"`
+	OkElement    string `xml:"ok-element,omitempty"`
+	ErrElement   string `xml:"err-element,omitempty"`
+	NoopElement  string `xml:"noop-element,omitempty"`
+	RawInfo      []byte `xml:",innerxml"`
+}
+
+// rpcErrorTag is a sentinel error matching an RFC 6241 Appendix A
+// error-tag value. RPCError.Is compares against these by tag so callers
+// can write errors.Is(err, netconf.ErrLockDenied) instead of
+// string-matching on Message.
+type rpcErrorTag string
+
+func (t rpcErrorTag) Error() string {
+	return fmt.Sprintf("netconf: rpc-error tag %q", string(t))
+}
+
+// Sentinel errors for the standard error-tag values defined by RFC 6241
+// Appendix A.
+var (
+	ErrInUse                 = rpcErrorTag("in-use")
+	ErrInvalidValue          = rpcErrorTag("invalid-value")
+	ErrTooBig                = rpcErrorTag("too-big")
+	ErrMissingAttribute      = rpcErrorTag("missing-attribute")
+	ErrBadAttribute          = rpcErrorTag("bad-attribute")
+	ErrUnknownAttribute      = rpcErrorTag("unknown-attribute")
+	ErrMissingElement        = rpcErrorTag("missing-element")
+	ErrBadElement            = rpcErrorTag("bad-element")
+	ErrUnknownElement        = rpcErrorTag("unknown-element")
+	ErrUnknownNamespace      = rpcErrorTag("unknown-namespace")
+	ErrAccessDenied          = rpcErrorTag("access-denied")
+	ErrLockDenied            = rpcErrorTag("lock-denied")
+	ErrResourceDenied        = rpcErrorTag("resource-denied")
+	ErrRollbackFailed        = rpcErrorTag("rollback-failed")
+	ErrDataExists            = rpcErrorTag("data-exists")
+	ErrDataMissing           = rpcErrorTag("data-missing")
+	ErrOperationNotSupported = rpcErrorTag("operation-not-supported")
+	ErrOperationFailed       = rpcErrorTag("operation-failed")
+	ErrPartialOperation      = rpcErrorTag("partial-operation")
+	ErrMalformedMessage      = rpcErrorTag("malformed-message")
+)
+
+// Is reports whether target is the rpcErrorTag sentinel matching re's
+// error-tag, so that errors.Is(err, netconf.ErrLockDenied) works against
+// an *RPCError.
+func (re *RPCError) Is(target error) bool {
+	tag, ok := target.(rpcErrorTag)
+	if !ok {
+		return false
+	}
+	return re.Tag == string(tag)
+}
@@ -0,0 +1,165 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// FramingMode selects the wire framing used to delimit NETCONF messages.
+type FramingMode int
+
+const (
+	// FramingEOM is the NETCONF 1.0 ']]>]]>' end-of-message framing.
+	FramingEOM FramingMode = iota
+	// FramingChunked is the RFC 6242 NETCONF 1.1 chunked framing.
+	FramingChunked
+)
+
+// capBase11 is the capability URN advertised by peers that support
+// NETCONF 1.1 chunked framing.
+const capBase11 = "urn:ietf:params:netconf:base:1.1"
+
+// maxChunkSize is the largest chunk-size RFC 6242 allows in a chunk header.
+const maxChunkSize = 4294967295
+
+// maxChunkWrite bounds how much payload writeChunked puts in a single
+// chunk; large messages are simply split across several chunks.
+const maxChunkWrite = 1 << 16
+
+// writeFramed writes payload to w using the session's negotiated framing.
+func writeFramed(w io.Writer, mode FramingMode, payload []byte) error {
+	if mode == FramingChunked {
+		return writeChunked(w, payload)
+	}
+	_, err := w.Write(append(payload, []byte(delimEOM)...))
+	return err
+}
+
+// writeChunked frames payload per RFC 6242 section 4.2.
+func writeChunked(w io.Writer, payload []byte) error {
+	for len(payload) > 0 {
+		n := len(payload)
+		if n > maxChunkWrite {
+			n = maxChunkWrite
+		}
+		if _, err := fmt.Fprintf(w, "\n#%d\n", n); err != nil {
+			return err
+		}
+		if _, err := w.Write(payload[:n]); err != nil {
+			return err
+		}
+		payload = payload[n:]
+	}
+	_, err := io.WriteString(w, "\n##\n")
+	return err
+}
+
+// readFramed reads one message from r using the session's negotiated framing.
+func readFramed(r *bufio.Reader, mode FramingMode) ([]byte, error) {
+	if mode == FramingChunked {
+		return readChunked(r)
+	}
+	return readUntilEOMReader(r)
+}
+
+// readUntilEOMReader reads one ']]>]]>'-delimited message from an
+// already-wrapped *bufio.Reader, so chunked and EOM framing can share a
+// single buffered reader across a session's lifetime.
+func readUntilEOMReader(r *bufio.Reader) ([]byte, error) {
+	delim := []byte(delimEOM)
+	var buf []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b)
+		if bytes.HasSuffix(buf, delim) {
+			return buf[:len(buf)-len(delim)], nil
+		}
+	}
+}
+
+// readChunked reads one NETCONF 1.1 chunked message from r, reassembling
+// its chunks and validating each chunk-size header per RFC 6242.
+func readChunked(r *bufio.Reader) ([]byte, error) {
+	var msg []byte
+	for {
+		size, last, err := readChunkHeader(r)
+		if err != nil {
+			return nil, err
+		}
+		if last {
+			return msg, nil
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return nil, fmt.Errorf("netconf: short chunk, wanted %d bytes: %s", size, err)
+		}
+		msg = append(msg, chunk...)
+	}
+}
+
+// readChunkHeader reads a "\n#<size>\n" chunk header or a "\n##\n"
+// end-of-chunks marker, reporting the chunk size or last=true.
+func readChunkHeader(r *bufio.Reader) (size int, last bool, err error) {
+	if b, err := r.ReadByte(); err != nil {
+		return 0, false, err
+	} else if b != '\n' {
+		return 0, false, fmt.Errorf("netconf: malformed chunk header: expected LF, got %q", b)
+	}
+	if b, err := r.ReadByte(); err != nil {
+		return 0, false, err
+	} else if b != '#' {
+		return 0, false, fmt.Errorf("netconf: malformed chunk header: expected '#', got %q", b)
+	}
+
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, false, err
+	}
+	if b == '#' {
+		if b, err := r.ReadByte(); err != nil {
+			return 0, false, err
+		} else if b != '\n' {
+			return 0, false, fmt.Errorf("netconf: malformed end-of-chunks marker")
+		}
+		return 0, true, nil
+	}
+
+	var digits []byte
+	for b >= '0' && b <= '9' {
+		digits = append(digits, b)
+		if b, err = r.ReadByte(); err != nil {
+			return 0, false, err
+		}
+	}
+	if len(digits) == 0 {
+		return 0, false, fmt.Errorf("netconf: malformed chunk header: no digits")
+	}
+	if len(digits) > 1 && digits[0] == '0' {
+		return 0, false, fmt.Errorf("netconf: malformed chunk size %q: leading zero", digits)
+	}
+	if b != '\n' {
+		return 0, false, fmt.Errorf("netconf: malformed chunk header: expected LF after size, got %q", b)
+	}
+
+	n, err := strconv.ParseUint(string(digits), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("netconf: malformed chunk size %q: %s", digits, err)
+	}
+	if n == 0 || n > maxChunkSize {
+		return 0, false, fmt.Errorf("netconf: chunk size %d out of range", n)
+	}
+
+	return int(n), false, nil
+}
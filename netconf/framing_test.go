@@ -0,0 +1,68 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestChunkedRoundTrip(t *testing.T) {
+	cases := []string{
+		"",
+		"<rpc-reply message-id=\"1\"><ok/></rpc-reply>",
+		strings.Repeat("x", maxChunkWrite+100),
+	}
+
+	for _, want := range cases {
+		var buf bytes.Buffer
+		if err := writeChunked(&buf, []byte(want)); err != nil {
+			t.Fatalf("writeChunked: %s", err)
+		}
+
+		got, err := readChunked(bufio.NewReader(&buf))
+		if err != nil {
+			t.Fatalf("readChunked: %s", err)
+		}
+		if string(got) != want {
+			t.Fatalf("roundtrip mismatch: got %d bytes, want %d bytes", len(got), len(want))
+		}
+	}
+}
+
+func TestReadChunkedRejectsMalformedHeaders(t *testing.T) {
+	cases := map[string]string{
+		"missing leading LF":   "#4\nabcd\n##\n",
+		"non-numeric size":     "\n#abc\nabcd\n##\n",
+		"missing header LF":    "\n#4abcd\n##\n",
+		"oversized chunk":      "\n#4294967296\nabcd\n##\n",
+		"zero-size chunk":      "\n#0\nabcd\n##\n",
+		"short chunk body":     "\n#10\nabcd\n##\n",
+		"malformed end marker": "\n#4\nabcd\n#!\n",
+	}
+
+	for name, raw := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := readChunked(bufio.NewReader(strings.NewReader(raw))); err == nil {
+				t.Fatalf("expected error for %q", raw)
+			}
+		})
+	}
+}
+
+func FuzzReadChunked(f *testing.F) {
+	f.Add([]byte("\n#4\nabcd\n##\n"))
+	f.Add([]byte("\n#0\n\n##\n"))
+	f.Add([]byte(""))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Must never panic, regardless of how malformed the input is.
+		_, _ = readChunked(bufio.NewReader(bytes.NewReader(data)))
+	})
+}
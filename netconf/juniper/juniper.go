@@ -0,0 +1,101 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package juniper provides the Junos security/address-book configuration
+// schema that used to live in the netconf package directly. It is built
+// on top of netconf's schema-agnostic EditConfig and MethodGetConfig
+// primitives, kept here for callers migrating off the old hardcoded API.
+package juniper
+
+import (
+	"encoding/xml"
+
+	"github.com/snirt/go-netconf/netconf"
+)
+
+type AddressBookXML struct {
+	XMLName    xml.Name        `xml:"address-book,omitempty"`
+	Name       string          `xml:"name,omitempty"`
+	Address    []AddressXML    `xml:"address,omitempty"`
+	AddressSet []AddressSetXML `xml:"address-set,omitempty"`
+}
+
+type AddressXML struct {
+	XMLOperation string `xml:"operation,attr,omitempty"`
+	Name         string `xml:"name,omitempty"`
+	Description  string `xml:"description,omitempty"`
+	IPPrefix     string `xml:"ip-prefix,omitempty"`
+}
+
+type AddressSetXML struct {
+	XMLName        xml.Name        `xml:"address-set,omitempty"`
+	XMLOperation   string          `xml:"operation,attr,omitempty"`
+	Name           string          `xml:"name,omitempty"`
+	AddressSetName string          `xml:"address-set-name,omitempty"`
+	Description    string          `xml:"description,omitempty"`
+	Address        []AddressXML    `xml:"address,omitempty"`
+	AddressSet     []AddressSetXML `xml:"address-set,omitempty"`
+}
+
+type ConfigurationXML struct {
+	XMLName  xml.Name `xml:"configuration,omitempty"`
+	Security struct {
+		AddressBook AddressBookXML `xml:"address-book,omitempty"`
+	} `xml:"security,omitempty"`
+}
+
+// ToRawMethod builds a get-config request against running that filters on
+// this ConfigurationXML's address-book subtree.
+func (c ConfigurationXML) ToRawMethod() netconf.RawMethod {
+	m, _ := netconf.MethodGetConfig("running", c)
+	return m
+}
+
+// ParseConfiguration unmarshals the raw <data> contents of a get-config
+// reply (netconf.RPCReply.Data.Raw) into a ConfigurationXML tree.
+func ParseConfiguration(raw []byte) (*ConfigurationXML, error) {
+	c := &ConfigurationXML{}
+	if err := xml.Unmarshal(raw, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type EditConfigXML struct {
+	XMLName  xml.Name `xml:"configuration,omitempty"`
+	Security struct {
+		AddressBook AddressBookXML `xml:"address-book,omitempty"`
+	} `xml:"security,omitempty"`
+}
+
+// ToRawMethod builds an edit-config request against candidate carrying
+// this EditConfigXML's address-book subtree.
+func (ec EditConfigXML) ToRawMethod() netconf.RawMethod {
+	m, _ := netconf.EditConfig("candidate", ec)
+	return m
+}
+
+type DeleteConfigXML struct {
+	XMLName  xml.Name `xml:"configuration,omitempty"`
+	Security struct {
+		AddressBook AddressBookXML `xml:"address-book,omitempty"`
+	} `xml:"security,omitempty"`
+}
+
+// ToRawMethod builds an edit-config request against candidate that marks
+// every address and address-set directly under this DeleteConfigXML's
+// address book for deletion; entries nested inside an address-set are not
+// marked.
+func (dc DeleteConfigXML) ToRawMethod() netconf.RawMethod {
+	for i := range dc.Security.AddressBook.AddressSet {
+		dc.Security.AddressBook.AddressSet[i].XMLOperation = "delete"
+	}
+	for i := range dc.Security.AddressBook.Address {
+		dc.Security.AddressBook.Address[i].XMLOperation = "delete"
+	}
+	m, _ := netconf.EditConfig("candidate", dc)
+	return m
+}
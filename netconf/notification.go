@@ -0,0 +1,164 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// capNotification is the capability URN a peer must advertise in its hello
+// message to support RFC 5277 event notifications.
+const capNotification = "urn:ietf:params:netconf:capability:notification:1.0"
+
+// ErrNotificationsNotSupported is returned by Subscribe when the remote
+// peer did not advertise capNotification during the hello exchange.
+var ErrNotificationsNotSupported = errors.New("netconf: peer does not support notifications")
+
+// ErrNotificationOverflow is recorded on a Subscription, and retrievable
+// via Err, when a notification arrives faster than the caller drains
+// Notifications and has to be dropped to keep the reader goroutine from
+// blocking on a slow consumer.
+var ErrNotificationOverflow = errors.New("netconf: notification dropped, subscriber too slow")
+
+// notificationBufferSize is the capacity of a Subscription's notification
+// channel, smoothing brief bursts before ErrNotificationOverflow kicks in.
+const notificationBufferSize = 16
+
+// Notification represents a single <notification> pushed by the server
+// after a successful subscription.
+type Notification struct {
+	XMLName   xml.Name `xml:"notification"`
+	EventTime string   `xml:"eventTime"`
+	Event     string   `xml:",innerxml"`
+}
+
+// SubscribeOptions configures a create-subscription request.
+type SubscribeOptions struct {
+	// Stream is the notification stream to subscribe to. Defaults to
+	// "NETCONF" when empty, per RFC 5277.
+	Stream string
+	// Filter is an optional subtree or XPath filter restricting which
+	// notifications are delivered.
+	Filter string
+	// StartTime and StopTime bound a replay of past notifications. Both
+	// are optional and, per RFC 5277, must be RFC 3339 timestamps.
+	StartTime string
+	StopTime  string
+}
+
+// toRawMethod renders the create-subscription RPC body for opts.
+func (opts SubscribeOptions) toRawMethod() RawMethod {
+	stream := opts.Stream
+	if stream == "" {
+		stream = "NETCONF"
+	}
+
+	body := fmt.Sprintf("<stream>%s</stream>", stream)
+	if opts.Filter != "" {
+		body += fmt.Sprintf("<filter type=\"subtree\">%s</filter>", opts.Filter)
+	}
+	if opts.StartTime != "" {
+		body += fmt.Sprintf("<startTime>%s</startTime>", opts.StartTime)
+	}
+	if opts.StopTime != "" {
+		body += fmt.Sprintf("<stopTime>%s</stopTime>", opts.StopTime)
+	}
+
+	return RawMethod(fmt.Sprintf("<create-subscription xmlns=\"urn:ietf:params:xml:ns:netconf:notification:1.0\">%s</create-subscription>", body))
+}
+
+// Subscription is returned by Session.Subscribe and delivers notifications
+// pushed by the server until the session is closed or an error occurs.
+type Subscription struct {
+	notifications chan *Notification
+
+	mu  sync.Mutex
+	err error
+
+	closeOnce sync.Once
+}
+
+// Notifications returns the channel on which incoming notifications are
+// delivered. The channel is closed once the subscription ends; callers
+// should then check Err to distinguish a clean shutdown from a failure.
+func (s *Subscription) Notifications() <-chan *Notification {
+	return s.notifications
+}
+
+// Err returns the first error recorded on the subscription: either
+// ErrNotificationOverflow, which is recorded while the subscription stays
+// active, or the error that ended it. It returns nil if no error has been
+// recorded, including when the session was closed normally. Unlike reading
+// off a channel, Err does not consume anything: once an error is recorded,
+// every later call returns it.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// setErr records err on the subscription, if no error has already been
+// recorded.
+func (s *Subscription) setErr(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+}
+
+func (s *Subscription) close() {
+	s.closeOnce.Do(func() { close(s.notifications) })
+}
+
+// parseNotification unmarshals a raw <notification> message.
+func parseNotification(raw []byte) (*Notification, error) {
+	n := &Notification{}
+	if err := xml.Unmarshal(raw, n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Subscribe issues a create-subscription RPC per opts and, on success,
+// returns a Subscription that streams notifications pushed by the server.
+// It fails with ErrNotificationsNotSupported if the peer never advertised
+// capNotification during the hello exchange. ctx bounds the
+// create-subscription exchange only; cancelling it does not tear down a
+// subscription that already succeeded.
+func (s *Session) Subscribe(ctx context.Context, opts SubscribeOptions) (*Subscription, error) {
+	if !s.HasCapability(capNotification) {
+		return nil, ErrNotificationsNotSupported
+	}
+
+	reply, err := s.ExecContext(ctx, opts.toRawMethod())
+	if err != nil {
+		return nil, err
+	}
+	if !reply.Ok {
+		return nil, fmt.Errorf("netconf: create-subscription was not acknowledged")
+	}
+
+	sub := &Subscription{
+		notifications: make(chan *Notification, notificationBufferSize),
+	}
+	s.setSubscription(sub)
+
+	return sub, nil
+}
+
+// ListSubscriptionStreams issues the standard get against netconf-state/streams
+// and returns the raw reply for the caller to parse the available streams from.
+func (s *Session) ListSubscriptionStreams() (*RPCReply, error) {
+	filter := `<netconf-state xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring"><streams/></netconf-state>`
+	get := RawMethod(fmt.Sprintf(`<get><filter type="subtree">%s</filter></get>`, filter))
+	return s.Exec(get)
+}
@@ -0,0 +1,75 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+// capPartialLock is the capability URN a peer must advertise to support
+// RFC 5717 partial locking.
+const capPartialLock = "urn:ietf:params:netconf:capability:partial-lock:1.0"
+
+// ErrPartialLockNotSupported is returned by Session.PartialLock when the
+// remote peer did not advertise capPartialLock during the hello exchange.
+var ErrPartialLockNotSupported = errors.New("netconf: peer does not support partial-lock")
+
+// MethodPartialLock files an RFC 5717 partial-lock request against the
+// config nodes addressed by selects, a list of XPath expressions.
+func MethodPartialLock(selects []string) RawMethod {
+	var selectXML string
+	for _, sel := range selects {
+		selectXML += fmt.Sprintf("<select>%s</select>", sel)
+	}
+	return RawMethod(fmt.Sprintf(`<partial-lock xmlns="urn:ietf:params:xml:ns:netconf:partial-lock:1.0">%s</partial-lock>`, selectXML))
+}
+
+// MethodPartialUnlock files the RFC 5717 partial-unlock request releasing
+// the lock previously granted as lockID.
+func MethodPartialUnlock(lockID uint32) RawMethod {
+	return RawMethod(fmt.Sprintf(`<partial-unlock xmlns="urn:ietf:params:xml:ns:netconf:partial-lock:1.0"><lock-id>%d</lock-id></partial-unlock>`, lockID))
+}
+
+// PartialLockResult is the parsed response to a partial-lock request.
+type PartialLockResult struct {
+	LockID     uint32   `xml:"lock-id"`
+	LockedNode []string `xml:"locked-node"`
+}
+
+// partialLockReply models an <rpc-reply> carrying a partial-lock result:
+// per RFC 5717, <lock-id> and <locked-node> are direct children of
+// rpc-reply, not nested in a <partial-lock> wrapper element.
+type partialLockReply struct {
+	XMLName xml.Name `xml:"rpc-reply"`
+	PartialLockResult
+}
+
+// PartialLock issues a partial-lock request for selects and returns the
+// granted lock-id and the set of nodes it locked. It fails with
+// ErrPartialLockNotSupported if the peer never advertised capPartialLock.
+// ctx bounds the partial-lock exchange; cancelling it does not release a
+// lock that was already granted.
+func (s *Session) PartialLock(ctx context.Context, selects []string) (*PartialLockResult, error) {
+	if !s.HasCapability(capPartialLock) {
+		return nil, ErrPartialLockNotSupported
+	}
+
+	reply, err := s.ExecContext(ctx, MethodPartialLock(selects))
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed partialLockReply
+	if err := xml.Unmarshal([]byte(reply.RawReply), &parsed); err != nil {
+		return nil, err
+	}
+
+	return &parsed.PartialLockResult, nil
+}
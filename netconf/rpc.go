@@ -17,114 +17,24 @@ import (
 
 // Structs
 
-type AddressBookXML struct {
-	XMLName  xml.Name `xml:"address-book,omitempty"`
-	Name       string          `xml:"name,omitempty"`
-	Address    []AddressXML    `xml:"address,omitempty"`
-	AddressSet []AddressSetXML `xml:"address-set,omitempty"`
-}
-
-type AddressXML struct {
-	XMLOperation string `xml:"operation,attr,omitempty"`
-	Name        string `xml:"name,omitempty"`
-	Description string `xml:"description,omitempty"`
-	IPPrefix    string `xml:"ip-prefix,omitempty"`
-}
-
-type AddressSetXML struct {
-	XMLName  xml.Name `xml:"address-set,omitempty"`
-	XMLOperation string `xml:"operation,attr,omitempty"`
-	Name           string          `xml:"name,omitempty"`
-	AddressSetName string          `xml:"address-set-name,omitempty"`
-	Description    string          `xml:"description,omitempty"`
-	Address        []AddressXML    `xml:"address,omitempty"`
-	AddressSet     []AddressSetXML `xml:"address-set,omitempty"`
-}
-
-type ConfigurationXML struct {
-	XMLName  xml.Name `xml:"configuration,omitempty"`
-	Security struct {
-		AddressBook AddressBookXML `xml:"address-book,omitempty"`
-	} `xml:"security,omitempty"`
-}
-func trimXML(str string) string {
-	str = strings.Replace(str, "\t", "", -1)
-	return strings.Replace(str, "\n", "", -1)
-}
-
-// ToRawMethod chain xml string with marshalled xml
-func (c ConfigurationXML)ToRawMethod() RawMethod {
-	getConfigFmt :=
-	`<get-config>
-		<source>
-			<running/>
-		</source>
-		<filter type=\"subtree\">
-			%s
-		</filter>
-	</get-config>`
-	xmlStr ,_ := xml.Marshal(c)
-	fullXML := fmt.Sprintf(getConfigFmt, xmlStr)
-	return RawMethod(trimXML(fullXML))
-}
-
+// DataXML holds the raw, schema-agnostic contents of an <rpc-reply>'s
+// <data> element. Callers unmarshal Raw into whatever shape their target
+// device's schema requires; see the netconf/juniper subpackage for one
+// such schema.
 type DataXML struct {
-	XMLName  xml.Name `xml:"data,omitempty"`
-	Configuration ConfigurationXML `xml:"configuration"`
-}
-
-type EditConfigXML struct {
-	XMLName  xml.Name `xml:"configuration,omitempty"`
-	Security struct {
-		AddressBook AddressBookXML `xml:"address-book,omitempty"`
-	} `xml:"security,omitempty"`
-}
-
-func (ec EditConfigXML) ToRawMethod() RawMethod {
-	editConfigFmt :=
-	`<edit-config> 
-		<target> 
-			<candidate/> 
-		</target>
-		<config>
-			%s
-		</config>
-	</edit-config>`
-	xmlStr, _ := xml.Marshal(ec)
-	return RawMethod(trimXML(fmt.Sprintf(editConfigFmt, xmlStr)))
-}
-
-type DeleteConfigXML struct {
-	XMLName  xml.Name `xml:"configuration,omitempty"`
-	Security struct {
-		AddressBook AddressBookXML `xml:"address-book,omitempty"`
-	} `xml:"security,omitempty"`
-}
-
-func (dc DeleteConfigXML) ToRawMethod() RawMethod {
-	for i, _ := range dc.Security.AddressBook.AddressSet {
-		dc.Security.AddressBook.AddressSet[i].XMLOperation = "delete"
-	}
-	for i, _ := range dc.Security.AddressBook.Address {
-		dc.Security.AddressBook.Address[i].XMLOperation = "delete"
-	}
-	deleteConfigFmt :=
-	`<edit-config>
-		<target>
-			<candidate/>
-		</target>
-		<config>
-			%s
-		</config>
-	</edit-config>`
-	xmlStr, _ := xml.Marshal(dc)
-	return RawMethod(trimXML(fmt.Sprintf(deleteConfigFmt, xmlStr)))
+	XMLName xml.Name `xml:"data,omitempty"`
+	Raw     []byte   `xml:",innerxml"`
 }
 
 // RPCMessage represents an RPC Message to be sent.
 type RPCMessage struct {
 	MessageID string
 	Methods   []RPCMethod
+
+	// base11 selects the urn:ietf:params:xml:ns:netconf:base:1.1 namespace
+	// instead of base:1.0. It is set by the session once both peers have
+	// negotiated NETCONF 1.1 chunked framing.
+	base11 bool
 }
 
 // NewRPCMessage generates a new RPC Message structure with the provided methods
@@ -142,13 +52,18 @@ func (m *RPCMessage) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		buf.WriteString(method.MarshalMethod())
 	}
 
+	xmlns := "urn:ietf:params:xml:ns:netconf:base:1.0"
+	if m.base11 {
+		xmlns = "urn:ietf:params:xml:ns:netconf:base:1.1"
+	}
+
 	data := struct {
 		MessageID string `xml:"message-id,attr"`
 		Xmlns     string `xml:"xmlns,attr"`
 		Methods   []byte `xml:",innerxml"`
 	}{
 		m.MessageID,
-		"urn:ietf:params:xml:ns:netconf:base:1.0",
+		xmlns,
 		buf.Bytes(),
 	}
 
@@ -195,12 +110,12 @@ func NewRPCReply(rawXML []byte, ErrOnWarning bool, messageID string) (*RPCReply,
 
 // RPCError defines an error reply to a RPC request
 type RPCError struct {
-	Type     string `xml:"error-type"`
-	Tag      string `xml:"error-tag"`
-	Severity string `xml:"error-severity"`
-	Path     string `xml:"error-path"`
-	Message  string `xml:"error-message"`
-	Info     string `xml:",innerxml"`
+	Type     string    `xml:"error-type"`
+	Tag      string    `xml:"error-tag"`
+	Severity string    `xml:"error-severity"`
+	Path     string    `xml:"error-path"`
+	Message  string    `xml:"error-message"`
+	Info     ErrorInfo `xml:"error-info"`
 }
 
 // Error generates a string representation of the provided RPC error
@@ -231,9 +146,17 @@ func MethodUnlock(target string) RawMethod {
 	return RawMethod(fmt.Sprintf("<unlock><target><%s/></target></unlock>", target))
 }
 
-// MethodGetConfig files a NETCONF get-config source request with the remote host
-func MethodGetConfig(source string) RawMethod {
-	return RawMethod(fmt.Sprintf("<get-config><source><%s/></source></get-config>", source))
+// MethodGetConfig files a NETCONF get-config source request with the
+// remote host. filter restricts the returned subtree and may be nil (no
+// filter), a raw subtree payload (string, []byte, or xml.Marshaler), or
+// an XPathFilter. opts may set a WithDefaults mode.
+func MethodGetConfig(source string, filter interface{}, opts ...GetOpt) (RawMethod, error) {
+	options := resolveGetOptions(opts)
+	f, err := renderFilter(filter)
+	if err != nil {
+		return "", err
+	}
+	return RawMethod(fmt.Sprintf("<get-config><source><%s/></source>%s%s</get-config>", source, f, renderWithDefaults(options.withDefaults))), nil
 }
 
 // MethodCommit commit changes
@@ -0,0 +1,356 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrSessionClosed is returned by Exec/ExecContext and the RFC 5717/5277
+// helpers built on them once the session has been closed and no error has
+// been recorded for it. Once the reader goroutine has recorded an error,
+// such as a transport error, those calls return that error instead.
+var ErrSessionClosed = errors.New("netconf: session closed")
+
+// delimEOM is the NETCONF 1.0 end-of-message framing delimiter.
+const delimEOM = "]]>]]>"
+
+// Session represents an established NETCONF session with a remote device.
+// It owns the transport connection and a background reader goroutine that
+// demultiplexes incoming framed messages into RPC replies, matched by
+// message-id, and asynchronous notifications.
+type Session struct {
+	transport    io.ReadWriteCloser
+	reader       *bufio.Reader
+	framing      FramingMode
+	capabilities []string
+	sessionID    string
+	withDefaults withDefaultsNegotiation
+
+	preferredVersion string
+
+	mu      sync.Mutex
+	pending map[string]chan []byte
+	err     error // set once, by the first dispatchErr: a transport error or a malformed notification
+
+	subscription *Subscription
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// SessionOption configures optional Session behavior at construction time.
+type SessionOption func(*Session)
+
+// WithPreferredVersion sets the NETCONF base version ("1.0" or "1.1") the
+// session prefers. "1.0" pins the session to end-of-message framing and
+// leaves base:1.1 out of our hello; any other value leaves framing to be
+// negotiated from the peer's advertised capabilities, so "1.1" still falls
+// back to end-of-message framing when the peer lacks base:1.1.
+func WithPreferredVersion(version string) SessionOption {
+	return func(s *Session) { s.preferredVersion = version }
+}
+
+// NewSession performs the NETCONF hello exchange over transport, negotiates
+// framing, and starts the background reader goroutine.
+func NewSession(transport io.ReadWriteCloser, opts ...SessionOption) (*Session, error) {
+	s := &Session{
+		transport: transport,
+		reader:    bufio.NewReader(transport),
+		pending:   make(map[string]chan []byte),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.exchangeHello(); err != nil {
+		return nil, err
+	}
+
+	go s.readLoop()
+
+	return s, nil
+}
+
+// HasCapability reports whether the remote peer advertised the given
+// capability URN during the hello exchange.
+func (s *Session) HasCapability(capability string) bool {
+	for _, c := range s.capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// FramingMode reports the wire framing negotiated for this session.
+func (s *Session) FramingMode() FramingMode {
+	return s.framing
+}
+
+// Close terminates the session's transport and stops the reader goroutine.
+func (s *Session) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return s.transport.Close()
+}
+
+// helloMessage models the <hello> exchanged before any RPCs are sent.
+type helloMessage struct {
+	XMLName      xml.Name `xml:"hello"`
+	Capabilities []string `xml:"capabilities>capability"`
+	SessionID    string   `xml:"session-id,omitempty"`
+}
+
+// exchangeHello sends our hello message, parses the peer's reply, and
+// negotiates framing: chunked framing is used only if both peers
+// advertised base:1.1 and the caller didn't pin preferredVersion to "1.0".
+func (s *Session) exchangeHello() error {
+	ours := helloMessage{
+		Capabilities: []string{
+			"urn:ietf:params:netconf:base:1.0",
+			capBase11,
+		},
+	}
+	if s.preferredVersion == "1.0" {
+		ours.Capabilities = ours.Capabilities[:1]
+	}
+
+	out, err := xml.Marshal(ours)
+	if err != nil {
+		return err
+	}
+	if err := writeFramed(s.transport, FramingEOM, out); err != nil {
+		return err
+	}
+
+	raw, err := readFramed(s.reader, FramingEOM)
+	if err != nil {
+		return err
+	}
+
+	var theirs helloMessage
+	if err := xml.Unmarshal(raw, &theirs); err != nil {
+		return fmt.Errorf("netconf: invalid hello message: %s", err)
+	}
+
+	s.capabilities = theirs.Capabilities
+	s.sessionID = theirs.SessionID
+	s.withDefaults = parseWithDefaultsCapability(theirs.Capabilities)
+
+	if s.preferredVersion != "1.0" && s.HasCapability(capBase11) {
+		s.framing = FramingChunked
+	}
+
+	return nil
+}
+
+// messageIDOf extracts the message-id attribute from a raw rpc-reply, or
+// "" if the message carries none (as is the case for notifications).
+func messageIDOf(raw []byte) string {
+	var tag struct {
+		MessageID string `xml:"message-id,attr"`
+	}
+	if err := xml.Unmarshal(raw, &tag); err != nil {
+		return ""
+	}
+	return tag.MessageID
+}
+
+// rootElementOf returns the local name of raw's top-level XML element, so
+// callers can demux by tag instead of scanning the payload for a substring
+// that could also appear inside an rpc-reply's data.
+func rootElementOf(raw []byte) string {
+	var tag struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(raw, &tag); err != nil {
+		return ""
+	}
+	return tag.XMLName.Local
+}
+
+// readLoop continuously reads framed messages off the transport and
+// dispatches them either to the RPC reply channel waiting on their
+// message-id or to the active subscription's notification channel.
+func (s *Session) readLoop() {
+	for {
+		raw, err := readFramed(s.reader, s.framing)
+		if err != nil {
+			s.dispatchErr(err)
+			return
+		}
+
+		if rootElementOf(raw) == "notification" {
+			s.dispatchNotification(raw)
+			continue
+		}
+
+		id := messageIDOf(raw)
+		s.mu.Lock()
+		ch, ok := s.pending[id]
+		if ok {
+			delete(s.pending, id)
+		}
+		s.mu.Unlock()
+		if ok {
+			ch <- raw
+		}
+	}
+}
+
+// dispatchNotification delivers n to the active subscription's channel
+// without blocking: the reader goroutine also demuxes RPC replies, so a
+// subscriber that isn't keeping up must not be able to stall Exec calls.
+// A notification that doesn't fit the channel's buffer is dropped and
+// recorded as the subscription's error.
+func (s *Session) dispatchNotification(raw []byte) {
+	n, err := parseNotification(raw)
+	if err != nil {
+		s.dispatchErr(err)
+		return
+	}
+	sub := s.getSubscription()
+	if sub == nil {
+		return
+	}
+	select {
+	case sub.notifications <- n:
+	default:
+		sub.setErr(ErrNotificationOverflow)
+	}
+}
+
+// dispatchErr records err as the session's terminal error, fails every
+// pending Exec/ExecContext call waiting on a reply, and signals done so
+// that callers waiting for a reply, or about to register a new pending
+// entry in send, stop instead of hanging on a dead transport. A write
+// already blocked on the transport is not interrupted.
+func (s *Session) dispatchErr(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	pending := s.pending
+	s.pending = make(map[string]chan []byte)
+	s.mu.Unlock()
+
+	s.closeOnce.Do(func() { close(s.done) })
+
+	for _, ch := range pending {
+		close(ch)
+	}
+
+	sub := s.getSubscription()
+	if sub == nil {
+		return
+	}
+	// io.EOF means the transport was closed normally; Err should stay nil
+	// in that case, matching its documented contract.
+	if err != io.EOF {
+		sub.setErr(err)
+	}
+	sub.close()
+}
+
+// setSubscription installs sub as the session's active subscription.
+func (s *Session) setSubscription(sub *Subscription) {
+	s.mu.Lock()
+	s.subscription = sub
+	s.mu.Unlock()
+}
+
+// getSubscription returns the session's active subscription, or nil if
+// none has been established.
+func (s *Session) getSubscription() *Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.subscription
+}
+
+// send writes a framed RPC message and returns a channel on which the raw
+// reply will be delivered once the reader goroutine matches its message-id.
+func (s *Session) send(msg *RPCMessage) (chan []byte, error) {
+	msg.base11 = s.framing == FramingChunked
+
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	if err := enc.Encode(msg); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan []byte, 1)
+	s.mu.Lock()
+	if s.err != nil {
+		err := s.err
+		s.mu.Unlock()
+		return nil, err
+	}
+	select {
+	case <-s.done:
+		s.mu.Unlock()
+		return nil, ErrSessionClosed
+	default:
+	}
+	s.pending[msg.MessageID] = ch
+	s.mu.Unlock()
+
+	if err := writeFramed(s.transport, s.framing, buf.Bytes()); err != nil {
+		s.mu.Lock()
+		delete(s.pending, msg.MessageID)
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	return ch, nil
+}
+
+// readErr returns the error that ended the session: the transport error
+// readLoop exited with, or ErrSessionClosed if it ended cleanly.
+func (s *Session) readErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	return ErrSessionClosed
+}
+
+// Exec sends the given methods as a single RPC and blocks for the reply.
+func (s *Session) Exec(methods ...RPCMethod) (*RPCReply, error) {
+	return s.ExecContext(context.Background(), methods...)
+}
+
+// ExecContext sends the given methods as a single RPC and blocks for the
+// reply, the session closing, or ctx being done, whichever comes first. If
+// the session's reader goroutine exits because of a transport error, every
+// call blocked here fails with that error instead of hanging forever.
+func (s *Session) ExecContext(ctx context.Context, methods ...RPCMethod) (*RPCReply, error) {
+	msg := NewRPCMessage(methods)
+	ch, err := s.send(msg)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case raw, ok := <-ch:
+		if !ok {
+			return nil, s.readErr()
+		}
+		return NewRPCReply(raw, false, msg.MessageID)
+	case <-s.done:
+		return nil, s.readErr()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,176 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// capWithDefaultsPrefix is the capability URN prefix advertised by peers
+// that support RFC 6243 with-defaults handling. The full URI carries
+// basic-mode and also-supported query parameters. This identifies the
+// capability during hello negotiation only; it is not an XML namespace.
+const capWithDefaultsPrefix = "urn:ietf:params:netconf:capability:with-defaults:1.0"
+
+// nsWithDefaults is the XML namespace RFC 6243 assigns to the
+// <with-defaults> request element and the wd:default reply attribute.
+const nsWithDefaults = "urn:ietf:params:xml:ns:netconf:with-defaults:1.0"
+
+// ErrWithDefaultsNotSupported is returned when a WithDefaults mode is
+// requested but the peer never advertised the with-defaults capability.
+var ErrWithDefaultsNotSupported = errors.New("netconf: peer does not support with-defaults")
+
+// ErrUnsupportedWithDefaultsMode is returned when the requested
+// WithDefaults mode isn't the peer's basic-mode or in its also-supported
+// list.
+var ErrUnsupportedWithDefaultsMode = errors.New("netconf: with-defaults mode not supported by peer")
+
+// GetOpt customizes the RPC produced by MethodGetConfig or MethodGet.
+type GetOpt func(*getOptions)
+
+type getOptions struct {
+	withDefaults string
+}
+
+// WithDefaults sets <with-defaults> to "report-all", "report-all-tagged",
+// "trim", or "explicit", per RFC 6243.
+func WithDefaults(mode string) GetOpt {
+	return func(o *getOptions) { o.withDefaults = mode }
+}
+
+func resolveGetOptions(opts []GetOpt) getOptions {
+	var o getOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func renderWithDefaults(mode string) string {
+	if mode == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<with-defaults xmlns="%s">%s</with-defaults>`, nsWithDefaults, mode)
+}
+
+// MethodGet files a NETCONF get request with the remote host, optionally
+// restricted by filter (see MethodGetConfig) and customized by opts.
+func MethodGet(filter interface{}, opts ...GetOpt) (RawMethod, error) {
+	options := resolveGetOptions(opts)
+	f, err := renderFilter(filter)
+	if err != nil {
+		return "", err
+	}
+	return RawMethod(fmt.Sprintf("<get>%s%s</get>", f, renderWithDefaults(options.withDefaults))), nil
+}
+
+// Element is a minimal generic XML tree node for callers that want to
+// walk a DataXML.Raw payload without a schema of their own.
+type Element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Content  []byte     `xml:",innerxml"`
+	Children []Element  `xml:",any"`
+}
+
+// IsDefault reports whether node was tagged wd:default="true" by the
+// server, per RFC 6243 report-all-tagged mode.
+func IsDefault(node Element) bool {
+	for _, a := range node.Attrs {
+		if a.Name.Local == "default" && a.Name.Space == nsWithDefaults && a.Value == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// withDefaultsNegotiation records the basic-mode and also-supported modes
+// a peer advertised in its with-defaults capability URI.
+type withDefaultsNegotiation struct {
+	supported     bool
+	basicMode     string
+	alsoSupported []string
+}
+
+// parseWithDefaultsCapability scans capabilities for the with-defaults
+// capability and extracts its basic-mode and also-supported parameters.
+func parseWithDefaultsCapability(capabilities []string) withDefaultsNegotiation {
+	for _, c := range capabilities {
+		if !strings.HasPrefix(c, capWithDefaultsPrefix) {
+			continue
+		}
+		n := withDefaultsNegotiation{supported: true}
+
+		_, query, found := strings.Cut(c, "?")
+		if !found {
+			return n
+		}
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return n
+		}
+		n.basicMode = values.Get("basic-mode")
+		if also := values.Get("also-supported"); also != "" {
+			n.alsoSupported = strings.Split(also, ",")
+		}
+		return n
+	}
+	return withDefaultsNegotiation{}
+}
+
+// validateWithDefaults checks mode against the negotiated with-defaults
+// capability, returning ErrUnsupportedWithDefaultsMode if the peer didn't
+// advertise support for it.
+func (s *Session) validateWithDefaults(mode string) error {
+	if mode == "" {
+		return nil
+	}
+	if !s.withDefaults.supported {
+		return ErrWithDefaultsNotSupported
+	}
+	if mode == s.withDefaults.basicMode {
+		return nil
+	}
+	for _, m := range s.withDefaults.alsoSupported {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrUnsupportedWithDefaultsMode, mode)
+}
+
+// GetConfig retrieves configuration from source, optionally restricted by
+// filter and customized by opts, validating any WithDefaults mode against
+// the peer's negotiated with-defaults capability before sending.
+func (s *Session) GetConfig(source string, filter interface{}, opts ...GetOpt) (*RPCReply, error) {
+	if err := s.validateWithDefaults(resolveGetOptions(opts).withDefaults); err != nil {
+		return nil, err
+	}
+	m, err := MethodGetConfig(source, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s.Exec(m)
+}
+
+// Get retrieves state and configuration data, optionally restricted by
+// filter and customized by opts, validating any WithDefaults mode against
+// the peer's negotiated with-defaults capability before sending.
+func (s *Session) Get(filter interface{}, opts ...GetOpt) (*RPCReply, error) {
+	if err := s.validateWithDefaults(resolveGetOptions(opts).withDefaults); err != nil {
+		return nil, err
+	}
+	m, err := MethodGet(filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s.Exec(m)
+}